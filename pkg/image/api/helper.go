@@ -3,19 +3,30 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
-
-	"github.com/fsouza/go-dockerclient"
 )
 
 // DockerDefaultNamespace is the value for namespace when a single segment name is provided.
 const DockerDefaultNamespace = "library"
 
+// DockerDigestAlgorithm is the only digest algorithm currently accepted in a pull spec's
+// "@<algorithm>:<hex>" suffix.
+const DockerDigestAlgorithm = "sha256"
+
+// dockerDigestHexLength is the length in hex characters of a sha256 digest.
+const dockerDigestHexLength = 64
+
+// dockerDigestHexPattern matches a valid sha256 digest hex portion: exactly
+// dockerDigestHexLength lowercase or uppercase hex characters.
+var dockerDigestHexPattern = regexp.MustCompile(fmt.Sprintf(`^[A-Fa-f0-9]{%d}$`, dockerDigestHexLength))
+
 // SplitDockerPullSpec breaks a Docker pull specification into its components, or returns
 // an error if those components are not valid. Attempts to match as closely as possible the
-// Docker spec up to 1.3. Future API revisions may change the pull syntax.
-func SplitDockerPullSpec(spec string) (registry, namespace, name, ref string, err error) {
-	registry, namespace, name, ref, err = SplitOpenShiftPullSpec(spec)
+// Docker spec up to 1.3. Future API revisions may change the pull syntax. A pull spec may carry
+// a tag, a digest, or both at once (e.g. "name:tag@sha256:...") in which case both are returned.
+func SplitDockerPullSpec(spec string) (registry, namespace, name, tag, digest string, err error) {
+	registry, namespace, name, tag, digest, err = SplitOpenShiftPullSpec(spec)
 	if err != nil {
 		return
 	}
@@ -23,69 +34,91 @@ func SplitDockerPullSpec(spec string) (registry, namespace, name, ref string, er
 }
 
 // SplitOpenShiftPullSpec breaks an OpenShift pull specification into its components, or returns
-// an error if those components are not valid. Attempts to match as closely as possible the
-// Docker spec up to 1.3. Future API revisions may change the pull syntax.
-func SplitOpenShiftPullSpec(spec string) (registry, namespace, name, ref string, err error) {
-	spec, ref = docker.ParseRepositoryTag(spec)
-	arr := strings.Split(spec, "/")
-	switch len(arr) {
-	case 2:
-		return "", arr[0], arr[1], ref, nil
-	case 3:
-		return arr[0], arr[1], arr[2], ref, nil
-	case 1:
-		if len(arr[0]) == 0 {
-			err = fmt.Errorf("the docker pull spec %q must be two or three segments separated by slashes", spec)
-			return
-		}
-		return "", "", arr[0], ref, nil
-	default:
-		err = fmt.Errorf("the docker pull spec %q must be two or three segments separated by slashes", spec)
-		return
+// an error if those components are not valid. Delegates to ParseImageReference, which models
+// the Docker distribution reference grammar.
+func SplitOpenShiftPullSpec(spec string) (registry, namespace, name, tag, digest string, err error) {
+	ref, err := ParseImageReference(spec)
+	if err != nil {
+		return "", "", "", "", "", err
+	}
+	return ref.Registry, ref.Namespace, ref.Name, ref.Tag, ref.Digest, nil
+}
+
+// splitDockerDigest separates a trailing "@<algorithm>:<hex>" digest suffix from spec, if one is
+// present, validating that the algorithm is supported and the hex portion is the expected length.
+func splitDockerDigest(spec string) (rest, digest string, err error) {
+	i := strings.Index(spec, "@")
+	if i == -1 {
+		return spec, "", nil
 	}
+	rest, digest = spec[:i], spec[i+1:]
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || parts[0] != DockerDigestAlgorithm || !dockerDigestHexPattern.MatchString(parts[1]) {
+		return "", "", fmt.Errorf("the digest %q must be of the form %s:<%d hex characters>", digest, DockerDigestAlgorithm, dockerDigestHexLength)
+	}
+	return rest, digest, nil
 }
 
 // IsPullSpec returns true if the provided string appears to be a valid Docker pull spec.
 func IsPullSpec(spec string) bool {
-	_, _, _, _, err := SplitDockerPullSpec(spec)
+	_, err := ParseImageReference(spec)
 	return err == nil
 }
 
 // JoinDockerPullSpec turns a set of components of a Docker pull specification into a single
-// string. Attempts to match as closely as possible the Docker spec up to 1.3. Future API
-// revisions may change the pull syntax.
-func JoinDockerPullSpec(registry, namespace, name, ref string) string {
-	if len(ref) != 0 {
-		if strings.Contains(ref, ":") {
-			// v2 digest
-			ref = "@" + ref
-		} else {
-			ref = ":" + ref
-		}
-	}
-	if len(namespace) == 0 {
-		if len(registry) == 0 {
-			return fmt.Sprintf("%s%s", name, ref)
-		}
-		namespace = DockerDefaultNamespace
-	}
-	if len(registry) == 0 {
-		return fmt.Sprintf("%s/%s%s", namespace, name, ref)
-	}
-	return fmt.Sprintf("%s/%s/%s%s", registry, namespace, name, ref)
+// string. When both tag and digest are provided the result carries both, e.g.
+// "repo:tag@sha256:...".
+func JoinDockerPullSpec(registry, namespace, name, tag, digest string) string {
+	return ImageReference{Registry: registry, Namespace: namespace, Name: name, Tag: tag, Digest: digest}.String()
+}
+
+// manifestEnvelope captures just enough of a manifest or manifest list to determine which shape
+// it has, without assuming the schema 1 layout. Manifests is only populated for a manifest list
+// or OCI image index; an OCI index in particular often omits mediaType altogether, since it is
+// conveyed via the HTTP Content-Type instead, so a non-empty Manifests is treated as the
+// authoritative signal that this is a list rather than a single manifest.
+type manifestEnvelope struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	MediaType     string              `json:"mediaType"`
+	Manifests     []manifestListEntry `json:"manifests"`
 }
 
-// ImageWithMetadata returns a copy of image with the DockerImageMetadata filled in
-// from the raw DockerImageManifest data stored in the image.
+// ImageWithMetadata returns a copy of image with the DockerImageMetadata, and for multi-platform
+// images DockerImageManifests, filled in from the raw DockerImageManifest data stored in the
+// image. Schema 1, schema 2, OCI image manifests, and manifest lists / OCI image indexes are all
+// understood.
 func ImageWithMetadata(image Image) (*Image, error) {
 	if len(image.DockerImageManifest) == 0 {
 		return &image, nil
 	}
 
 	manifestData := image.DockerImageManifest
-
 	image.DockerImageManifest = ""
 
+	envelope := manifestEnvelope{}
+	if err := json.Unmarshal([]byte(manifestData), &envelope); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(envelope.Manifests) > 0 || envelope.MediaType == DockerManifestListSchema2MediaType || envelope.MediaType == OCIImageIndexMediaType:
+		image.DockerImageManifests = manifestListPlatforms(envelope.Manifests)
+		return &image, nil
+
+	case envelope.SchemaVersion == 2 || envelope.MediaType == DockerManifestSchema2MediaType || envelope.MediaType == OCIManifestMediaType:
+		if err := imageWithMetadataFromConfig(&image, manifestData); err != nil {
+			return nil, err
+		}
+		return &image, nil
+
+	default:
+		return imageWithMetadataFromSchema1(image, manifestData)
+	}
+}
+
+// imageWithMetadataFromSchema1 populates DockerImageMetadata from a schema 1 manifest's
+// embedded v1Compatibility history entry.
+func imageWithMetadataFromSchema1(image Image, manifestData string) (*Image, error) {
 	manifest := DockerImageManifest{}
 	if err := json.Unmarshal([]byte(manifestData), &manifest); err != nil {
 		return nil, err
@@ -116,6 +149,119 @@ func ImageWithMetadata(image Image) (*Image, error) {
 	return &image, nil
 }
 
+// dockerManifestSchema2 is the subset of a schema 2 / OCI manifest needed to total up the image
+// size from its layers and to record the config blob's digest as recorded by the registry.
+type dockerManifestSchema2 struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+}
+
+// dockerImageConfig is the subset of an OCI/Docker image config blob needed to populate
+// DockerImageMetadata for schema 2 and OCI images. Config and ContainerConfig are kept as raw
+// JSON so they can be unmarshaled straight into the existing DockerImageMetadata fields,
+// whatever their concrete type.
+type dockerImageConfig struct {
+	Architecture    string          `json:"architecture"`
+	Container       string          `json:"container"`
+	DockerVersion   string          `json:"docker_version"`
+	Author          string          `json:"author"`
+	Created         json.RawMessage `json:"created"`
+	Config          json.RawMessage `json:"config"`
+	ContainerConfig json.RawMessage `json:"container_config"`
+}
+
+// imageWithMetadataFromConfig populates DockerImageMetadata for a schema 2 or OCI manifest from
+// the config blob referenced by the manifest, which the importer must have already fetched into
+// image.DockerImageConfig. Images stored before config-blob plumbing existed won't have one; in
+// that case the image is left untouched, matching the historical behavior of returning an
+// image as-is when its metadata cannot be derived.
+func imageWithMetadataFromConfig(image *Image, manifestData string) error {
+	if len(image.DockerImageConfig) == 0 {
+		return nil
+	}
+
+	config := dockerImageConfig{}
+	if err := json.Unmarshal([]byte(image.DockerImageConfig), &config); err != nil {
+		return err
+	}
+
+	image.DockerImageMetadata.Architecture = config.Architecture
+	image.DockerImageMetadata.Container = config.Container
+	image.DockerImageMetadata.DockerVersion = config.DockerVersion
+	image.DockerImageMetadata.Author = config.Author
+
+	if len(config.Created) > 0 {
+		if err := json.Unmarshal(config.Created, &image.DockerImageMetadata.Created); err != nil {
+			return err
+		}
+	}
+	if len(config.Config) > 0 {
+		if err := json.Unmarshal(config.Config, &image.DockerImageMetadata.Config); err != nil {
+			return err
+		}
+	}
+	if len(config.ContainerConfig) > 0 {
+		if err := json.Unmarshal(config.ContainerConfig, &image.DockerImageMetadata.ContainerConfig); err != nil {
+			return err
+		}
+	}
+
+	manifest := dockerManifestSchema2{}
+	if err := json.Unmarshal([]byte(manifestData), &manifest); err != nil {
+		return err
+	}
+
+	var size int64
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	image.DockerImageMetadata.Size = size
+
+	// Record the config descriptor digest exactly as the manifest names it, rather than
+	// re-hashing DockerImageConfig - the stored config blob is not guaranteed to be byte-for-byte
+	// identical to what the registry addressed if it was ever re-marshaled on import.
+	image.DockerImageMetadata.ID = manifest.Config.Digest
+
+	return nil
+}
+
+// manifestListEntry is a single platform-specific manifest descriptor as it appears in a
+// manifest list / OCI image index's "manifests" array.
+type manifestListEntry struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+	Size      int64  `json:"size"`
+	Platform  struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		Variant      string `json:"variant"`
+	} `json:"platform"`
+}
+
+// manifestListPlatforms converts the raw manifest list / OCI image index entries into the
+// per-platform ImageManifest descriptors callers use to pick the manifest matching their
+// platform.
+func manifestListPlatforms(entries []manifestListEntry) []ImageManifest {
+	manifests := make([]ImageManifest, 0, len(entries))
+	for _, m := range entries {
+		manifests = append(manifests, ImageManifest{
+			Digest:    m.Digest,
+			MediaType: m.MediaType,
+			Size:      m.Size,
+			Platform: ManifestPlatform{
+				OS:           m.Platform.OS,
+				Architecture: m.Platform.Architecture,
+				Variant:      m.Platform.Variant,
+			},
+		})
+	}
+	return manifests
+}
+
 // LatestTaggedImage returns the most recent TagEvent for the specified image
 // repository and tag.
 func LatestTaggedImage(repo ImageRepository, tag string) (*TagEvent, error) {
@@ -0,0 +1,131 @@
+package api
+
+import "testing"
+
+func TestImageWithMetadataSchema1(t *testing.T) {
+	image := Image{
+		Name: "sha256:" + repeat("1", 64),
+		DockerImageManifest: `{
+			"schemaVersion": 1,
+			"history": [{"v1Compatibility": "{\"id\":\"abc123\",\"architecture\":\"amd64\",\"size\":42}"}]
+		}`,
+	}
+
+	got, err := ImageWithMetadata(image)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.DockerImageManifest != "" {
+		t.Errorf("DockerImageManifest = %q, want cleared", got.DockerImageManifest)
+	}
+	if got.DockerImageMetadata.ID != "abc123" {
+		t.Errorf("ID = %q, want %q", got.DockerImageMetadata.ID, "abc123")
+	}
+	if got.DockerImageMetadata.Architecture != "amd64" {
+		t.Errorf("Architecture = %q, want %q", got.DockerImageMetadata.Architecture, "amd64")
+	}
+	if got.DockerImageMetadata.Size != 42 {
+		t.Errorf("Size = %d, want 42", got.DockerImageMetadata.Size)
+	}
+}
+
+func TestImageWithMetadataSchema2WithConfig(t *testing.T) {
+	image := Image{
+		Name: "sha256:" + repeat("2", 64),
+		DockerImageManifest: `{
+			"schemaVersion": 2,
+			"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+			"config": {"digest": "sha256:` + repeat("c", 64) + `", "size": 100},
+			"layers": [{"size": 10}, {"size": 20}]
+		}`,
+		DockerImageConfig: `{"architecture":"amd64","author":"me","docker_version":"18.09","container":"deadbeef"}`,
+	}
+
+	got, err := ImageWithMetadata(image)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.DockerImageMetadata.Architecture != "amd64" {
+		t.Errorf("Architecture = %q, want %q", got.DockerImageMetadata.Architecture, "amd64")
+	}
+	if got.DockerImageMetadata.Size != 30 {
+		t.Errorf("Size = %d, want 30", got.DockerImageMetadata.Size)
+	}
+	if want := "sha256:" + repeat("c", 64); got.DockerImageMetadata.ID != want {
+		t.Errorf("ID = %q, want %q", got.DockerImageMetadata.ID, want)
+	}
+}
+
+func TestImageWithMetadataSchema2WithoutConfig(t *testing.T) {
+	image := Image{
+		Name: "sha256:" + repeat("3", 64),
+		DockerImageManifest: `{
+			"schemaVersion": 2,
+			"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+			"config": {"digest": "sha256:` + repeat("d", 64) + `", "size": 100},
+			"layers": [{"size": 10}]
+		}`,
+	}
+
+	got, err := ImageWithMetadata(image)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.DockerImageMetadata.Architecture != "" {
+		t.Errorf("Architecture = %q, want empty when no config blob is stored", got.DockerImageMetadata.Architecture)
+	}
+	if got.DockerImageMetadata.ID != "" {
+		t.Errorf("ID = %q, want empty when no config blob is stored", got.DockerImageMetadata.ID)
+	}
+}
+
+func TestImageWithMetadataOCIManifest(t *testing.T) {
+	image := Image{
+		Name: "sha256:" + repeat("4", 64),
+		DockerImageManifest: `{
+			"schemaVersion": 2,
+			"mediaType": "application/vnd.oci.image.manifest.v1+json",
+			"config": {"digest": "sha256:` + repeat("e", 64) + `", "size": 50},
+			"layers": [{"size": 5}]
+		}`,
+		DockerImageConfig: `{"architecture":"arm64"}`,
+	}
+
+	got, err := ImageWithMetadata(image)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.DockerImageMetadata.Architecture != "arm64" {
+		t.Errorf("Architecture = %q, want %q", got.DockerImageMetadata.Architecture, "arm64")
+	}
+}
+
+func TestImageWithMetadataOCIIndexWithoutMediaType(t *testing.T) {
+	image := Image{
+		Name: "sha256:" + repeat("5", 64),
+		DockerImageManifest: `{
+			"schemaVersion": 2,
+			"manifests": [{
+				"digest": "sha256:` + repeat("f", 64) + `",
+				"mediaType": "application/vnd.oci.image.manifest.v1+json",
+				"size": 500,
+				"platform": {"os": "linux", "architecture": "amd64"}
+			}]
+		}`,
+	}
+
+	got, err := ImageWithMetadata(image)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.DockerImageManifests) != 1 {
+		t.Fatalf("DockerImageManifests = %#v, want 1 entry", got.DockerImageManifests)
+	}
+	manifest := got.DockerImageManifests[0]
+	if want := "sha256:" + repeat("f", 64); manifest.Digest != want {
+		t.Errorf("Digest = %q, want %q", manifest.Digest, want)
+	}
+	if manifest.Platform.OS != "linux" || manifest.Platform.Architecture != "amd64" {
+		t.Errorf("Platform = %#v, want linux/amd64", manifest.Platform)
+	}
+}
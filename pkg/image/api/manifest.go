@@ -0,0 +1,37 @@
+package api
+
+// Media types recognized when deciding how to interpret a DockerImageManifest blob. Schema 1
+// carries no mediaType of its own, so the schema 1 path remains the default when none of these
+// match and the manifest has no "manifests" array and a schemaVersion other than 2.
+const (
+	DockerManifestSchema2MediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+	DockerManifestListSchema2MediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	OCIManifestMediaType               = "application/vnd.oci.image.manifest.v1+json"
+	OCIImageIndexMediaType             = "application/vnd.oci.image.index.v1+json"
+)
+
+// ManifestPlatform describes the target platform of a manifest referenced from a manifest list
+// or OCI image index.
+type ManifestPlatform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// ImageManifest describes a single platform-specific manifest referenced by a manifest list or
+// OCI image index, letting callers choose the manifest that matches their platform without
+// resolving every child image.
+type ImageManifest struct {
+	Digest    string           `json:"digest"`
+	MediaType string           `json:"mediaType"`
+	Size      int64            `json:"size"`
+	Platform  ManifestPlatform `json:"platform"`
+}
+
+// ImageLayer describes a single layer blob referenced by an Image's manifest, in the order the
+// manifest lists them. Name is the layer's content digest, e.g. "sha256:...".
+type ImageLayer struct {
+	Name      string `json:"name"`
+	LayerSize int64  `json:"size"`
+	MediaType string `json:"mediaType"`
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+// DockerDefaultRegistry is the host name recognized by the Docker client as Docker Hub.
+const DockerDefaultRegistry = "docker.io"
+
+// DockerDefaultV1Registry is the legacy host name for Docker Hub's v1 registry, still accepted
+// as an explicit registry component.
+const DockerDefaultV1Registry = "index.docker.io"
+
+// referenceComponentRegexp matches a single path component of an image name as defined by the
+// Docker distribution reference grammar: lowercase alphanumerics, optionally separated by '.',
+// '_', '__', or one or more '-'.
+var referenceComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]+)[a-z0-9]+)*$`)
+
+// ImageReference represents the components of a Docker pull spec, modeled on the reference
+// grammar implemented by github.com/docker/distribution/reference. Unlike the naive
+// slash-splitting this type replaces, a leading path segment is only treated as a registry host
+// when it contains a '.' or a ':', or is literally "localhost" - otherwise it is folded into the
+// namespace, matching how the Docker daemon itself disambiguates "myhost/name" from
+// "namespace/name".
+type ImageReference struct {
+	Registry  string
+	Namespace string
+	Name      string
+	Tag       string
+	Digest    string
+}
+
+// ParseImageReference parses a Docker pull spec into its registry, namespace, name, tag, and
+// digest components, returning an error if the name does not conform to the reference grammar
+// or a digest is present but malformed.
+func ParseImageReference(spec string) (ImageReference, error) {
+	var ref ImageReference
+
+	spec, digest, err := splitDockerDigest(spec)
+	if err != nil {
+		return ImageReference{}, err
+	}
+	ref.Digest = digest
+
+	spec, ref.Tag = docker.ParseRepositoryTag(spec)
+
+	if parts := strings.SplitN(spec, "/", 2); len(parts) == 2 && isRegistryName(parts[0]) {
+		ref.Registry, spec = parts[0], parts[1]
+	}
+
+	if i := strings.LastIndex(spec, "/"); i != -1 {
+		ref.Namespace, ref.Name = spec[:i], spec[i+1:]
+	} else {
+		ref.Name = spec
+	}
+
+	if len(ref.Name) == 0 {
+		return ImageReference{}, fmt.Errorf("the docker pull spec %q must include an image name", spec)
+	}
+	if err := validateReferenceComponent(ref.Name); err != nil {
+		return ImageReference{}, err
+	}
+	if len(ref.Namespace) != 0 {
+		if err := validateReferenceComponent(ref.Namespace); err != nil {
+			return ImageReference{}, err
+		}
+	}
+
+	return ref, nil
+}
+
+// MustParseImageReference parses a Docker pull spec into an ImageReference, panicking if spec is
+// invalid. It is intended for use with string constants.
+func MustParseImageReference(spec string) ImageReference {
+	ref, err := ParseImageReference(spec)
+	if err != nil {
+		panic(err)
+	}
+	return ref
+}
+
+// isRegistryName returns true if segment should be interpreted as a registry host rather than a
+// namespace, matching Docker's own heuristic: it contains a '.' or a ':', or is "localhost".
+func isRegistryName(segment string) bool {
+	return segment == "localhost" || strings.ContainsAny(segment, ".:")
+}
+
+// validateReferenceComponent returns an error if any slash-separated part of s is not a valid
+// reference grammar component.
+func validateReferenceComponent(s string) error {
+	for _, part := range strings.Split(s, "/") {
+		if !referenceComponentRegexp.MatchString(part) {
+			return fmt.Errorf("the name %q is not a valid image reference component: it must be lowercase alphanumeric, optionally separated by '.', '_', '__', or '-'", s)
+		}
+	}
+	return nil
+}
+
+// String returns the pull spec representation of the reference.
+func (r ImageReference) String() string {
+	tagDigest := ""
+	if len(r.Tag) != 0 {
+		tagDigest += ":" + r.Tag
+	}
+	if len(r.Digest) != 0 {
+		tagDigest += "@" + r.Digest
+	}
+	switch {
+	case len(r.Registry) != 0 && len(r.Namespace) != 0:
+		return fmt.Sprintf("%s/%s/%s%s", r.Registry, r.Namespace, r.Name, tagDigest)
+	case len(r.Registry) != 0:
+		return fmt.Sprintf("%s/%s%s", r.Registry, r.Name, tagDigest)
+	case len(r.Namespace) != 0:
+		return fmt.Sprintf("%s/%s%s", r.Namespace, r.Name, tagDigest)
+	default:
+		return fmt.Sprintf("%s%s", r.Name, tagDigest)
+	}
+}
+
+// DockerClientDefaults returns a copy of the reference with the registry and namespace the
+// Docker client would assume when they are unset. The implicit "library" namespace is only
+// ever applied against Docker Hub - a reference that already names a custom registry is left
+// without a namespace default.
+func (r ImageReference) DockerClientDefaults() ImageReference {
+	if len(r.Registry) == 0 {
+		r.Registry = DockerDefaultRegistry
+		if len(r.Namespace) == 0 {
+			r.Namespace = DockerDefaultNamespace
+		}
+	}
+	return r
+}
+
+// Exact returns the fully qualified pull spec for the reference, resolving Docker Hub defaults
+// when no registry was specified.
+func (r ImageReference) Exact() string {
+	return r.DockerClientDefaults().String()
+}
+
+// DaemonMinimal returns a copy of the reference with the implicit Docker Hub registry and
+// "library" namespace removed, matching how the Docker daemon displays well-known images
+// (e.g. "docker.io/library/ruby" becomes "ruby", "docker.io/fedora/ruby" becomes "fedora/ruby").
+func (r ImageReference) DaemonMinimal() ImageReference {
+	switch r.Registry {
+	case DockerDefaultRegistry, DockerDefaultV1Registry:
+		r.Registry = ""
+		if r.Namespace == DockerDefaultNamespace {
+			r.Namespace = ""
+		}
+	}
+	return r
+}
+
+// AsRepository returns a copy of the reference with the tag and digest removed, leaving only
+// the repository identity.
+func (r ImageReference) AsRepository() ImageReference {
+	r.Tag = ""
+	r.Digest = ""
+	return r
+}
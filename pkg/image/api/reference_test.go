@@ -0,0 +1,158 @@
+package api
+
+import "testing"
+
+func TestParseImageReference(t *testing.T) {
+	tests := map[string]struct {
+		spec    string
+		want    ImageReference
+		wantErr bool
+	}{
+		"name only": {
+			spec: "foo",
+			want: ImageReference{Name: "foo"},
+		},
+		"namespace and name": {
+			spec: "library/foo",
+			want: ImageReference{Namespace: "library", Name: "foo"},
+		},
+		"registry without dot is folded into namespace": {
+			spec: "myhost/foo",
+			want: ImageReference{Namespace: "myhost", Name: "foo"},
+		},
+		"registry with dot is a registry": {
+			spec: "my.host/foo",
+			want: ImageReference{Registry: "my.host", Name: "foo"},
+		},
+		"registry with port is a registry": {
+			spec: "myhost:5000/foo",
+			want: ImageReference{Registry: "myhost:5000", Name: "foo"},
+		},
+		"localhost is always a registry": {
+			spec: "localhost/foo",
+			want: ImageReference{Registry: "localhost", Name: "foo"},
+		},
+		"registry, namespace and name": {
+			spec: "my.host:5000/namespace/foo",
+			want: ImageReference{Registry: "my.host:5000", Namespace: "namespace", Name: "foo"},
+		},
+		"tag": {
+			spec: "foo:v1",
+			want: ImageReference{Name: "foo", Tag: "v1"},
+		},
+		"digest": {
+			spec: "foo@sha256:" + repeat("0", 64),
+			want: ImageReference{Name: "foo", Digest: "sha256:" + repeat("0", 64)},
+		},
+		"tag and digest together": {
+			spec: "my.host:5000/namespace/foo:v1@sha256:" + repeat("a", 64),
+			want: ImageReference{Registry: "my.host:5000", Namespace: "namespace", Name: "foo", Tag: "v1", Digest: "sha256:" + repeat("a", 64)},
+		},
+		"invalid digest algorithm": {
+			spec:    "foo@md5:" + repeat("a", 64),
+			wantErr: true,
+		},
+		"invalid digest length": {
+			spec:    "foo@sha256:abcd",
+			wantErr: true,
+		},
+		"empty name": {
+			spec:    "my.host:5000/namespace/",
+			wantErr: true,
+		},
+		"invalid uppercase name": {
+			spec:    "Foo",
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseImageReference(test.spec)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error parsing %q, got none", test.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error parsing %q: %v", test.spec, err)
+			}
+			if got != test.want {
+				t.Errorf("ParseImageReference(%q) = %#v, want %#v", test.spec, got, test.want)
+			}
+		})
+	}
+}
+
+func repeat(s string, n int) string {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		out = append(out, s[0])
+	}
+	return string(out)
+}
+
+func TestImageReferenceString(t *testing.T) {
+	tests := map[string]struct {
+		ref  ImageReference
+		want string
+	}{
+		"name only": {
+			ref:  ImageReference{Name: "foo"},
+			want: "foo",
+		},
+		"namespace and name": {
+			ref:  ImageReference{Namespace: "library", Name: "foo"},
+			want: "library/foo",
+		},
+		"full": {
+			ref:  ImageReference{Registry: "my.host:5000", Namespace: "namespace", Name: "foo", Tag: "v1", Digest: "sha256:" + repeat("a", 64)},
+			want: "my.host:5000/namespace/foo:v1@sha256:" + repeat("a", 64),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.ref.String(); got != test.want {
+				t.Errorf("String() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestImageReferenceDockerClientDefaults(t *testing.T) {
+	ref := MustParseImageReference("foo")
+	got := ref.DockerClientDefaults()
+	if got.Registry != DockerDefaultRegistry || got.Namespace != DockerDefaultNamespace {
+		t.Errorf("DockerClientDefaults() = %#v, want registry %q and namespace %q", got, DockerDefaultRegistry, DockerDefaultNamespace)
+	}
+
+	custom := MustParseImageReference("my.host/foo")
+	got = custom.DockerClientDefaults()
+	if got.Namespace != "" {
+		t.Errorf("DockerClientDefaults() applied the library namespace to a custom registry: %#v", got)
+	}
+}
+
+func TestImageReferenceDaemonMinimal(t *testing.T) {
+	ref := ImageReference{Registry: DockerDefaultRegistry, Namespace: DockerDefaultNamespace, Name: "foo"}
+	got := ref.DaemonMinimal()
+	if got.Registry != "" || got.Namespace != "" {
+		t.Errorf("DaemonMinimal() = %#v, want registry and namespace stripped", got)
+	}
+
+	ref = ImageReference{Registry: DockerDefaultRegistry, Namespace: "fedora", Name: "ruby"}
+	got = ref.DaemonMinimal()
+	if got.Registry != "" || got.Namespace != "fedora" {
+		t.Errorf("DaemonMinimal() = %#v, want registry stripped and namespace kept", got)
+	}
+}
+
+func TestImageReferenceAsRepository(t *testing.T) {
+	ref := MustParseImageReference("my.host/namespace/foo:v1@sha256:" + repeat("a", 64))
+	got := ref.AsRepository()
+	if got.Tag != "" || got.Digest != "" {
+		t.Errorf("AsRepository() = %#v, want tag and digest removed", got)
+	}
+}
@@ -0,0 +1,26 @@
+package fake
+
+import (
+	v1 "github.com/openshift/openshift-apiserver/pkg/image/generated/internalclientset/typed/image/v1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeImageV1 implements ImageStreamImportsGetter, recording every call against its embedded action
+// tracker instead of contacting a registry or API server. It only covers ImageStreamImports,
+// since that is the only resource this package generates a client for so far.
+type FakeImageV1 struct {
+	*testing.Fake
+}
+
+// ImageStreamImports returns a fake ImageStreamImportInterface scoped to namespace.
+func (c *FakeImageV1) ImageStreamImports(namespace string) v1.ImageStreamImportInterface {
+	return &FakeImageStreamImports{c, namespace}
+}
+
+// RESTClient returns a nil RESTClient; it exists only to satisfy callers that expect one from a
+// real typed client and never need to use it against a fake.
+func (c *FakeImageV1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
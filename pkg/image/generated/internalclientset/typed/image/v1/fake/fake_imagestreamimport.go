@@ -0,0 +1,28 @@
+package fake
+
+import (
+	imagev1 "github.com/openshift/api/image/v1"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeImageStreamImports implements ImageStreamImportInterface
+type FakeImageStreamImports struct {
+	Fake *FakeImageV1
+	ns   string
+}
+
+var imagestreamimportsResource = schema.GroupVersionResource{Group: "image.openshift.io", Version: "v1", Resource: "imagestreamimports"}
+
+// Create takes the representation of a imageStreamImport and creates it. Returns the server's
+// representation of the imageStreamImport, and an error, if there is any. Controller tests can
+// use the fake clientset's action tracker to assert an import was requested without contacting a
+// live registry.
+func (c *FakeImageStreamImports) Create(imageStreamImport *imagev1.ImageStreamImport) (result *imagev1.ImageStreamImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(imagestreamimportsResource, c.ns, imageStreamImport), &imagev1.ImageStreamImport{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*imagev1.ImageStreamImport), err
+}
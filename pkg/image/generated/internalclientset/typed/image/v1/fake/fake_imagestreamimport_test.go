@@ -0,0 +1,45 @@
+package fake
+
+import (
+	"testing"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	testingcore "k8s.io/client-go/testing"
+)
+
+// TestFakeImageStreamImportsCreateRecordsAction exercises the action tracker directly, the way a
+// controller test would, rather than asserting anything about import resolution: with no reactor
+// registered, the fake returns an empty ImageStreamImport and records the object it was given.
+func TestFakeImageStreamImportsCreateRecordsAction(t *testing.T) {
+	fake := &testingcore.Fake{}
+	client := &FakeImageStreamImports{Fake: &FakeImageV1{fake}, ns: "myproject"}
+
+	in := &imagev1.ImageStreamImport{}
+	in.Name = "myimport"
+
+	out, err := client.Create(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || out.Name != "" {
+		t.Errorf("Create returned %#v, want the empty default object", out)
+	}
+
+	actions := fake.Actions()
+	if len(actions) != 1 {
+		t.Fatalf("got %d actions, want 1", len(actions))
+	}
+	createAction, ok := actions[0].(testingcore.CreateAction)
+	if !ok {
+		t.Fatalf("action %#v is not a CreateAction", actions[0])
+	}
+	if createAction.GetResource() != imagestreamimportsResource {
+		t.Errorf("action resource = %#v, want %#v", createAction.GetResource(), imagestreamimportsResource)
+	}
+	if createAction.GetNamespace() != "myproject" {
+		t.Errorf("action namespace = %q, want %q", createAction.GetNamespace(), "myproject")
+	}
+	if obj, ok := createAction.GetObject().(*imagev1.ImageStreamImport); !ok || obj.Name != in.Name {
+		t.Errorf("action object = %#v, want name %q", createAction.GetObject(), in.Name)
+	}
+}
@@ -0,0 +1,47 @@
+package v1
+
+import (
+	imagev1 "github.com/openshift/api/image/v1"
+	rest "k8s.io/client-go/rest"
+)
+
+// ImageStreamImportsGetter has a method to return a ImageStreamImportInterface.
+type ImageStreamImportsGetter interface {
+	ImageStreamImports(namespace string) ImageStreamImportInterface
+}
+
+// ImageStreamImportInterface has methods to work with ImageStreamImport resources. Unlike most
+// generated resource interfaces, ImageStreamImport only supports Create: submitting one triggers
+// a batch import against the remote registry and returns the resolved images and per-tag status
+// in the response object, without otherwise behaving like a stored resource.
+type ImageStreamImportInterface interface {
+	Create(imageStreamImport *imagev1.ImageStreamImport) (*imagev1.ImageStreamImport, error)
+}
+
+// imageStreamImports implements ImageStreamImportInterface.
+type imageStreamImports struct {
+	client rest.Interface
+	ns     string
+}
+
+// newImageStreamImports returns an imageStreamImports scoped to namespace.
+func newImageStreamImports(c rest.Interface, namespace string) *imageStreamImports {
+	return &imageStreamImports{
+		client: c,
+		ns:     namespace,
+	}
+}
+
+// Create takes the representation of a imageStreamImport and creates it. Returns the server's
+// representation of the imageStreamImport, which carries the resolved Image objects and
+// per-tag import conditions, and an error, if there is any.
+func (c *imageStreamImports) Create(imageStreamImport *imagev1.ImageStreamImport) (result *imagev1.ImageStreamImport, err error) {
+	result = &imagev1.ImageStreamImport{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("imagestreamimports").
+		Body(imageStreamImport).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,42 @@
+package prune
+
+import "fmt"
+
+// ImageDeleter deletes Image objects from the API.
+type ImageDeleter interface {
+	DeleteImage(name string) error
+}
+
+// BlobDeleter deletes layer, config, and manifest blobs from a registry.
+type BlobDeleter interface {
+	DeleteBlob(digest string) error
+}
+
+// Execute deletes every Image and Blob in plan, in the plan's stable order, using images and
+// blobs. A failed or interrupted run can be retried: recomputing the Plan excludes anything
+// already deleted. Images are deleted before blobs, so once an Image is gone a recomputed Plan no
+// longer lists the blobs unique to it, and a retry does not delete them. When options.DryRun is
+// set, Execute only prints what it would delete.
+func Execute(plan *Plan, images ImageDeleter, blobs BlobDeleter, options Options) error {
+	for _, image := range plan.Images {
+		if options.DryRun {
+			fmt.Printf("would delete image %s\n", image.Name)
+			continue
+		}
+		if err := images.DeleteImage(image.Name); err != nil {
+			return fmt.Errorf("error deleting image %s: %v", image.Name, err)
+		}
+	}
+
+	for _, blob := range plan.Blobs {
+		if options.DryRun {
+			fmt.Printf("would delete blob %s\n", blob)
+			continue
+		}
+		if err := blobs.DeleteBlob(blob); err != nil {
+			return fmt.Errorf("error deleting blob %s: %v", blob, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package prune
+
+import "github.com/openshift/openshift-apiserver/pkg/image/api"
+
+// StreamFilter reports whether an ImageStream should be considered when computing a prune Plan.
+// Filters are composed the same way libimage builds its filter chains: a stream is considered
+// only if every filter in the chain returns true for it.
+type StreamFilter func(stream api.ImageStream) bool
+
+// NamespaceFilter limits the ImageStreams considered to those in the given namespace; references
+// from streams in other namespaces then do not keep an Image from being pruned.
+func NamespaceFilter(namespace string) StreamFilter {
+	return func(stream api.ImageStream) bool {
+		return stream.Namespace == namespace
+	}
+}
+
+// LabelFilter limits the ImageStreams considered to those whose labels match every key/value
+// pair in selector; references from other streams then do not keep an Image from being pruned.
+func LabelFilter(selector map[string]string) StreamFilter {
+	return func(stream api.ImageStream) bool {
+		for k, v := range selector {
+			if stream.Labels[k] != v {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// matchesAll reports whether stream satisfies every filter in filters.
+func matchesAll(stream api.ImageStream, filters []StreamFilter) bool {
+	for _, filter := range filters {
+		if !filter(stream) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+// Package prune computes and executes plans for deleting Image objects, and the layer/blob
+// digests unique to them, once no ImageStream's tag history references them any more.
+package prune
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/openshift-apiserver/pkg/image/api"
+)
+
+// Options controls how a prune Plan is computed.
+type Options struct {
+	// KeepYoungerThan keeps the Images of tag history items created more recently than this
+	// cutoff, even beyond KeepTagRevisions. It applies to tag history items only: an Image that
+	// no considered tag history references is pruned whatever its age. The zero value disables
+	// the cutoff.
+	KeepYoungerThan time.Time
+	// KeepTagRevisions is the number of most recent tag history items kept per tag, across all
+	// ImageStreams, regardless of KeepYoungerThan.
+	KeepTagRevisions int
+	// DryRun causes Execute to print the Plan instead of deleting anything.
+	DryRun bool
+	// Filters narrows the ImageStreams considered when deciding what is still referenced; a
+	// stream must satisfy every filter to be considered.
+	Filters []StreamFilter
+}
+
+// Plan describes the Images no longer referenced by any considered ImageStream, in a stable
+// name order, and the blob digests unique to those images once they are gone.
+type Plan struct {
+	// Images are the Image objects safe to delete.
+	Images []api.Image
+	// Blobs are the layer, config, and manifest digests referenced only by Images, not by any
+	// manifest that survives pruning.
+	Blobs []string
+}
+
+// Pruner computes prune Plans for a fixed set of ImageStreams and Images.
+type Pruner interface {
+	Plan() (*Plan, error)
+}
+
+type pruner struct {
+	streams []api.ImageStream
+	images  map[string]api.Image
+	options Options
+}
+
+// NewPruner returns a Pruner that considers every stream in streams that satisfies
+// options.Filters when deciding which of images are still referenced.
+func NewPruner(streams []api.ImageStream, images []api.Image, options Options) Pruner {
+	byName := make(map[string]api.Image, len(images))
+	for _, image := range images {
+		byName[image.Name] = image
+	}
+	return &pruner{streams: streams, images: byName, options: options}
+}
+
+// Plan walks the tag history of every considered ImageStream to determine which Images are
+// still referenced, then returns everything else - plus the blobs unique to it - as a Plan
+// whose Images are sorted by name so repeated runs produce the same order and a partially
+// executed Plan can simply be recomputed and re-executed.
+func (p *pruner) Plan() (*Plan, error) {
+	keepImages := map[string]bool{}
+	keepBlobs := map[string]bool{}
+
+	for _, stream := range p.streams {
+		if !matchesAll(stream, p.options.Filters) {
+			continue
+		}
+		for _, history := range stream.Status.Tags {
+			for i, item := range history.Items {
+				if i >= p.options.KeepTagRevisions && p.prunable(item.Created.Time) {
+					continue
+				}
+				keepImages[item.Image] = true
+			}
+		}
+	}
+
+	for name := range keepImages {
+		if image, ok := p.images[name]; ok {
+			for _, blob := range imageBlobs(image) {
+				keepBlobs[blob] = true
+			}
+		}
+	}
+
+	plan := &Plan{}
+	plannedBlobs := map[string]bool{}
+	for name, image := range p.images {
+		if keepImages[name] {
+			continue
+		}
+		plan.Images = append(plan.Images, image)
+		for _, blob := range imageBlobs(image) {
+			if keepBlobs[blob] || plannedBlobs[blob] {
+				continue
+			}
+			plannedBlobs[blob] = true
+			plan.Blobs = append(plan.Blobs, blob)
+		}
+	}
+
+	sort.Slice(plan.Images, func(i, j int) bool { return plan.Images[i].Name < plan.Images[j].Name })
+	sort.Strings(plan.Blobs)
+
+	return plan, nil
+}
+
+// prunable reports whether a tag history item created at t falls outside KeepYoungerThan, and
+// so is eligible for pruning once it is no longer among the KeepTagRevisions most recent items.
+func (p *pruner) prunable(t time.Time) bool {
+	return p.options.KeepYoungerThan.IsZero() || t.Before(p.options.KeepYoungerThan)
+}
+
+// imageBlobs returns every blob digest an Image references: its own manifest digest, the
+// per-platform manifest digests recorded for a multi-arch image, its layer blobs, and - for
+// schema 2 / OCI images - its config blob. These are exactly the digests that must stay
+// referenced by some surviving image for a blob to be kept; anything else is unique to the
+// pruned image and goes in Plan.Blobs.
+func imageBlobs(image api.Image) []string {
+	blobs := []string{image.Name}
+
+	for _, manifest := range image.DockerImageManifests {
+		blobs = append(blobs, manifest.Digest)
+	}
+
+	for _, layer := range image.DockerImageLayers {
+		blobs = append(blobs, layer.Name)
+	}
+
+	// ImageWithMetadata records the config descriptor digest from the manifest itself (as
+	// recorded by the registry) in DockerImageMetadata.ID for schema 2 / OCI images - schema 1
+	// images have no separate config blob, and their legacy v1 ID is never digest-shaped.
+	if id := image.DockerImageMetadata.ID; strings.HasPrefix(id, api.DockerDigestAlgorithm+":") {
+		blobs = append(blobs, id)
+	}
+
+	return blobs
+}